@@ -2,13 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	schema_migrator "github.com/SigNoz/signoz-otel-collector/cmd/signozschemamigrator/schema_migrator"
@@ -19,6 +25,37 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// tlsMode mirrors schema_migrator.TLSMode for the flags registered on the sync command.
+type tlsMode string
+
+const (
+	tlsModeDisabled   tlsMode = "disabled"
+	tlsModeSystem     tlsMode = "system"
+	tlsModeSkipVerify tlsMode = "skip-verify"
+	tlsModeClient     tlsMode = "client"
+	tlsModeMTLS       tlsMode = "mtls"
+)
+
+// tlsConfigArgs mirrors schema_migrator.TLSConfig for the flags registered on the sync command.
+type tlsConfigArgs struct {
+	mode      tlsMode
+	certDir   string
+	certName  string
+	keyName   string
+	caName    string
+	autoCerts bool
+}
+
+// acmeConfigArgs mirrors schema_migrator.ACMEProvider for the flags registered on the sync
+// command. When directory is set, it takes priority over tls.certName/tls.keyName: the client
+// certificate is obtained from the ACME server instead of being read from cert-dir.
+type acmeConfigArgs struct {
+	directory  string
+	eabKeyID   string
+	eabHMACKey string
+	identifier string
+}
+
 func getLogger() *zap.Logger {
 	// Always verbose logging for schema migrator
 	config := zap.NewDevelopmentConfig()
@@ -68,6 +105,7 @@ func main() {
 
 	registerSyncMigrate(cmd)
 	registerAsyncMigrate(cmd)
+	registerPlanMigrate(cmd)
 
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)
@@ -75,33 +113,103 @@ func main() {
 }
 
 func createTLSConfig(args *runSyncMigrateArgs) (*tls.Config, error) {
-	// custom tls config for full mtls enabled clickhouse
-
-	// dir := "/home/ubuntu/clickhouse/volume/internal"
-	dir := args.certDir
-	certName := args.certName
-	keyName := args.keyName
-	caName := args.caName
-	certFile := fmt.Sprintf("%s/%s", dir, certName)
-	privateKeyFile := fmt.Sprintf("%s/%s", dir, keyName)
-	caFile := fmt.Sprintf("%s/%s", dir, caName)
-
-	log.Printf("regSyncMig> Loading cert/key... Cert=%s Key=%s", certFile, privateKeyFile)
-	cert, err := tls.LoadX509KeyPair(certFile, privateKeyFile)
+	// custom tls config for full mtls enabled clickhouse, dispatched on args.tls.mode
+	switch args.tls.mode {
+	case "", tlsModeDisabled:
+		return nil, nil
+	case tlsModeSystem:
+		log.Printf("regSyncMig> Using system trust store for TLS, no client certificate")
+		return &tls.Config{}, nil
+	case tlsModeSkipVerify:
+		log.Printf("regSyncMig> WARNING: TLS certificate verification is disabled")
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	case tlsModeClient:
+		return createClientTLSConfig(args)
+	case tlsModeMTLS:
+		return createMTLSConfig(args)
+	default:
+		return nil, fmt.Errorf("unknown tls mode: %q", args.tls.mode)
+	}
+}
+
+func createClientTLSConfig(args *runSyncMigrateArgs) (*tls.Config, error) {
+	dir := args.tls.certDir
+	caFile := fmt.Sprintf("%s/%s", dir, args.tls.caName)
+
+	caCertPool, err := loadCAPool(caFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load client key pair: %w", err)
+		if !args.tls.autoCerts {
+			return nil, err
+		}
+		log.Printf("regSyncMig> No CA found, generating ephemeral dev CA/cert")
+		cert, pool, genErr := generateDevCertificate()
+		if genErr != nil {
+			return nil, fmt.Errorf("failed to generate dev certificate: %w", genErr)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{*cert}, RootCAs: pool}, nil
+	}
+
+	tlsConfig := &tls.Config{RootCAs: caCertPool}
+
+	if args.acme.directory != "" {
+		cert, err := obtainACMECertificate(args)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+		return tlsConfig, nil
+	}
+
+	certFile := fmt.Sprintf("%s/%s", dir, args.tls.certName)
+	keyFile := fmt.Sprintf("%s/%s", dir, args.tls.keyName)
+	if fileExists(certFile) && fileExists(keyFile) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func createMTLSConfig(args *runSyncMigrateArgs) (*tls.Config, error) {
+	dir := args.tls.certDir
+	certFile := fmt.Sprintf("%s/%s", dir, args.tls.certName)
+	privateKeyFile := fmt.Sprintf("%s/%s", dir, args.tls.keyName)
+	caFile := fmt.Sprintf("%s/%s", dir, args.tls.caName)
+
+	if args.acme.directory == "" && args.tls.autoCerts && !(fileExists(certFile) && fileExists(privateKeyFile) && fileExists(caFile)) {
+		log.Printf("regSyncMig> No cert/key/CA found, generating ephemeral dev CA/cert for mtls")
+		cert, pool, err := generateDevCertificate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate dev certificate: %w", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{*cert}, RootCAs: pool}, nil
+	}
+
+	var cert tls.Certificate
+	if args.acme.directory != "" {
+		acmeCert, err := obtainACMECertificate(args)
+		if err != nil {
+			return nil, err
+		}
+		cert = *acmeCert
+	} else {
+		log.Printf("regSyncMig> Loading cert/key... Cert=%s Key=%s", certFile, privateKeyFile)
+		loaded, err := tls.LoadX509KeyPair(certFile, privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key pair: %w", err)
+		}
+		cert = loaded
 	}
 
 	log.Printf("regSyncMig> Loading CA cert... Ca=%s", caFile)
-	caCert, err := os.ReadFile(caFile)
+	caCertPool, err := loadCAPool(caFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		return nil, err
 	}
 
-	log.Printf("regSyncMig> Creating cert pool...")
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-
 	log.Printf("regSyncMig> Making TLS config...")
 	tlsConfig := &tls.Config{
 		Certificates: []tls.Certificate{cert},
@@ -112,6 +220,91 @@ func createTLSConfig(args *runSyncMigrateArgs) (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// obtainACMECertificate bootstraps a short-lived client identity from the configured ACME server
+// (e.g. step-ca), for use in place of a cert/key pair read off disk. The key material lives only
+// in args.acmeProvider, which RunSyncMigrate discards once the migration finishes.
+func obtainACMECertificate(args *runSyncMigrateArgs) (*tls.Certificate, error) {
+	cert, err := args.acmeProvider.Obtain(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain ACME certificate: %w", err)
+	}
+	return cert, nil
+}
+
+func loadCAPool(caFile string) (*x509.CertPool, error) {
+	caCert, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse ca certificate")
+	}
+	return caCertPool, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// generateDevCertificate creates an ephemeral, in-memory self-signed CA and client certificate
+// for auto-certs, mirroring schema_migrator's generateDevCertificate.
+func generateDevCertificate() (*tls.Certificate, *x509.CertPool, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate ca key: %w", err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signoz-schema-migrator dev CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create ca certificate: %w", err)
+	}
+
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse generated ca certificate: %w", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate client key: %w", err)
+	}
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "signoz-schema-migrator dev client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client certificate: %w", err)
+	}
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+
+	return &tls.Certificate{
+		Certificate: [][]byte{leafDER},
+		PrivateKey:  leafKey,
+	}, caPool, nil
+}
+
 type runSyncMigrateArgs struct {
 	dsn                string
 	clusterName        string
@@ -119,10 +312,13 @@ type runSyncMigrateArgs struct {
 	development        bool
 	upVersions         []uint64
 	downVersions       []uint64
-	certDir            string
-	certName           string
-	keyName            string
-	caName             string
+	dryRun             bool
+	tls                tlsConfigArgs
+	acme               acmeConfigArgs
+
+	// acmeProvider is set by RunSyncMigrate from acme before creating the TLS config, and holds
+	// the ephemeral ACME identity's key material for the duration of the run.
+	acmeProvider *schema_migrator.ACMEProvider
 }
 
 func RunSyncMigrate(args *runSyncMigrateArgs) error {
@@ -134,15 +330,67 @@ func RunSyncMigrate(args *runSyncMigrateArgs) error {
 		return fmt.Errorf("cannot provide both up and down migrations")
 	}
 
+	if args.acme.directory != "" {
+		args.acmeProvider = &schema_migrator.ACMEProvider{
+			DirectoryURL: args.acme.directory,
+			Identifier:   args.acme.identifier,
+			EABKeyID:     args.acme.eabKeyID,
+			EABHMACKey:   args.acme.eabHMACKey,
+		}
+		defer args.acmeProvider.Discard()
+	}
+
+	manager, err := openMigrationManager(args, logger)
+	if err != nil {
+		return err
+	}
+
+	if args.dryRun {
+		plan, err := manager.Plan(context.Background(), args.upVersions, args.downVersions)
+		if err != nil {
+			return fmt.Errorf("failed to compute migration plan: %w", err)
+		}
+		fmt.Println(plan.String())
+		if plan.HasDrift() {
+			return fmt.Errorf("migration plan detected %d drifted migration(s): applied on the cluster but missing from this binary", len(plan.Drift))
+		}
+		return nil
+	}
+
+	err = manager.Bootstrap()
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap migrations: %w", err)
+	}
+	logger.Info("Bootstrapped migrations")
+
+	err = manager.RunSquashedMigrations(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to run squashed migrations: %w", err)
+	}
+	logger.Info("Ran squashed migrations")
+
+	if len(args.downVersions) != 0 {
+		logger.Info("Migrating down")
+		return manager.MigrateDownSync(context.Background(), args.downVersions)
+	}
+	logger.Info("Migrating up")
+	return manager.MigrateUpSync(context.Background(), args.upVersions)
+}
+
+// openMigrationManager parses the DSN, builds the TLS config, opens the ClickHouse connection,
+// and constructs the MigrationManager described by args. RunSyncMigrate's dry-run path and the
+// plan subcommand share this so a plan always reflects the exact same connection and cluster
+// configuration a real run would use.
+func openMigrationManager(args *runSyncMigrateArgs, logger *zap.Logger) (*schema_migrator.MigrationManager, error) {
 	opts, err := clickhouse.ParseDSN(args.dsn)
 	if err != nil {
-		return fmt.Errorf("failed to parse dsn: %w", err)
+		return nil, fmt.Errorf("failed to parse dsn: %w", err)
 	}
 	logger.Info("Parsed DSN", zap.Any("opts", opts))
 
 	tlsConfig, err := createTLSConfig(args)
 	if err != nil {
-		return fmt.Errorf("failed to get tls config: %w", err)
+		return nil, fmt.Errorf("failed to get tls config: %w", err)
 	}
 
 	opts.TLS = tlsConfig
@@ -152,7 +400,7 @@ func RunSyncMigrate(args *runSyncMigrateArgs) error {
 
 	conn, err := clickhouse.Open(opts)
 	if err != nil {
-		return fmt.Errorf("failed to open connection: %w", err)
+		return nil, fmt.Errorf("failed to open connection: %w", err)
 	}
 	logger.Info("Opened connection")
 
@@ -165,32 +413,26 @@ func RunSyncMigrate(args *runSyncMigrateArgs) error {
 		schema_migrator.WithDevelopment(args.development),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create migration manager: %w", err)
-	}
-	err = manager.Bootstrap()
-	if err != nil {
-		return fmt.Errorf("failed to bootstrap migrations: %w", err)
-	}
-	logger.Info("Bootstrapped migrations")
-
-	err = manager.RunSquashedMigrations(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to run squashed migrations: %w", err)
+		return nil, fmt.Errorf("failed to create migration manager: %w", err)
 	}
-	logger.Info("Ran squashed migrations")
-
-	if len(args.downVersions) != 0 {
-		logger.Info("Migrating down")
-		return manager.MigrateDownSync(context.Background(), args.downVersions)
-	}
-	logger.Info("Migrating up")
-	return manager.MigrateUpSync(context.Background(), args.upVersions)
+	return manager, nil
 }
 
 func registerSyncMigrate(cmd *cobra.Command) {
 
 	var upVersions string
 	var downVersions string
+	var mode string
+	var certDir string
+	var certName string
+	var keyName string
+	var caName string
+	var autoCerts bool
+	var acmeDirectory string
+	var acmeEABKeyID string
+	var acmeEABHMAC string
+	var acmeIdentifier string
+	var dryRun bool
 
 	syncCmd := &cobra.Command{
 		Use:   "sync",
@@ -228,10 +470,17 @@ func registerSyncMigrate(cmd *cobra.Command) {
 			// certName := "fullchain.crt"
 			// keyName := "private_migration.key"
 			// caName := "partialchain.crt"
+			mode := cmd.Flags().Lookup("tls-mode").Value.String()
 			certDir := cmd.Flags().Lookup("cert-dir").Value.String()
 			certName := cmd.Flags().Lookup("cert-name").Value.String()
 			keyName := cmd.Flags().Lookup("key-name").Value.String()
 			caName := cmd.Flags().Lookup("ca-name").Value.String()
+			autoCerts := strings.ToLower(cmd.Flags().Lookup("auto-certs").Value.String()) == "true"
+			acmeDirectory := cmd.Flags().Lookup("acme-directory").Value.String()
+			acmeEABKeyID := cmd.Flags().Lookup("acme-eab-kid").Value.String()
+			acmeEABHMAC := cmd.Flags().Lookup("acme-eab-hmac").Value.String()
+			acmeIdentifier := cmd.Flags().Lookup("acme-identifier").Value.String()
+			dryRun := strings.ToLower(cmd.Flags().Lookup("dry-run").Value.String()) == "true"
 
 			return RunSyncMigrate(&runSyncMigrateArgs{
 				dsn:                dsn,
@@ -240,20 +489,191 @@ func registerSyncMigrate(cmd *cobra.Command) {
 				development:        development,
 				upVersions:         upVersions,
 				downVersions:       downVersions,
-				certDir:            certDir,
-				certName:           certName,
-				keyName:            keyName,
-				caName:             caName,
+				dryRun:             dryRun,
+				tls: tlsConfigArgs{
+					// tls-mode defaults to mtls so existing cert-dir/cert-name/key-name/ca-name
+					// invocations keep working unchanged.
+					mode:      tlsMode(mode),
+					certDir:   certDir,
+					certName:  certName,
+					keyName:   keyName,
+					caName:    caName,
+					autoCerts: autoCerts,
+				},
+				acme: acmeConfigArgs{
+					// acme-directory, when set, takes priority over cert-name/key-name: the client
+					// certificate is obtained from the ACME server instead of being read from disk.
+					directory:  acmeDirectory,
+					eabKeyID:   acmeEABKeyID,
+					eabHMACKey: acmeEABHMAC,
+					identifier: acmeIdentifier,
+				},
 			})
 		},
 	}
 
 	syncCmd.Flags().StringVar(&upVersions, "up", "", "Up migrations to run, comma separated. Leave empty to run all up migrations")
 	syncCmd.Flags().StringVar(&downVersions, "down", "", "Down migrations to run, comma separated. Must provide down migrations explicitly to run")
+	syncCmd.Flags().StringVar(&mode, "tls-mode", string(tlsModeMTLS), "TLS mode: disabled, system, skip-verify, client, mtls")
+	syncCmd.Flags().StringVar(&certDir, "cert-dir", "", "Directory containing the TLS certificate, key, and CA files")
+	syncCmd.Flags().StringVar(&certName, "cert-name", "fullchain.crt", "Client certificate file name, relative to cert-dir")
+	syncCmd.Flags().StringVar(&keyName, "key-name", "private_migration.key", "Client private key file name, relative to cert-dir")
+	syncCmd.Flags().StringVar(&caName, "ca-name", "partialchain.crt", "CA certificate file name, relative to cert-dir")
+	syncCmd.Flags().BoolVar(&autoCerts, "auto-certs", false, "Generate an ephemeral self-signed CA/cert in memory when client/mtls mode has no cert files, for local development")
+	syncCmd.Flags().StringVar(&acmeDirectory, "acme-directory", "", "ACME server directory URL (e.g. step-ca) to obtain an ephemeral client certificate from, instead of reading cert-name/key-name from disk")
+	syncCmd.Flags().StringVar(&acmeEABKeyID, "acme-eab-kid", "", "External Account Binding key ID, required by private ACME servers such as step-ca")
+	syncCmd.Flags().StringVar(&acmeEABHMAC, "acme-eab-hmac", "", "External Account Binding HMAC key, base64url-encoded, paired with acme-eab-kid")
+	syncCmd.Flags().StringVar(&acmeIdentifier, "acme-identifier", "", "Identifier to request the ACME certificate for, used as the cert's CommonName/SAN")
+	syncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Compute and print the migration plan instead of applying it, exiting non-zero if drift is detected. No DDL is executed")
 
 	cmd.AddCommand(syncCmd)
 }
 
+// RunPlanMigrate computes the migration plan for args and prints it, without executing any DDL.
+// It returns an error if drift is detected, so CI pipelines can use `signoz-schema-migrator plan`
+// to gate deploys the same way RunSyncMigrate does with DryRun.
+func RunPlanMigrate(args *runSyncMigrateArgs, asJSON bool) error {
+	logger := getLogger()
+
+	if args.acme.directory != "" {
+		args.acmeProvider = &schema_migrator.ACMEProvider{
+			DirectoryURL: args.acme.directory,
+			Identifier:   args.acme.identifier,
+			EABKeyID:     args.acme.eabKeyID,
+			EABHMACKey:   args.acme.eabHMACKey,
+		}
+		defer args.acmeProvider.Discard()
+	}
+
+	manager, err := openMigrationManager(args, logger)
+	if err != nil {
+		return err
+	}
+
+	plan, err := manager.Plan(context.Background(), args.upVersions, args.downVersions)
+	if err != nil {
+		return fmt.Errorf("failed to compute migration plan: %w", err)
+	}
+
+	if asJSON {
+		out, err := plan.JSON()
+		if err != nil {
+			return fmt.Errorf("failed to marshal migration plan: %w", err)
+		}
+		fmt.Println(string(out))
+	} else {
+		fmt.Println(plan.String())
+	}
+
+	if plan.HasDrift() {
+		return fmt.Errorf("migration plan detected %d drifted migration(s): applied on the cluster but missing from this binary", len(plan.Drift))
+	}
+	return nil
+}
+
+func registerPlanMigrate(cmd *cobra.Command) {
+
+	var upVersions string
+	var downVersions string
+	var mode string
+	var certDir string
+	var certName string
+	var keyName string
+	var caName string
+	var autoCerts bool
+	var acmeDirectory string
+	var acmeEABKeyID string
+	var acmeEABHMAC string
+	var acmeIdentifier string
+	var asJSON bool
+
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Compute and print the migration plan without applying it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dsn := cmd.Flags().Lookup("dsn").Value.String()
+			replicationEnabled := strings.ToLower(cmd.Flags().Lookup("replication").Value.String()) == "true"
+			clusterName := cmd.Flags().Lookup("cluster-name").Value.String()
+			development := strings.ToLower(cmd.Flags().Lookup("dev").Value.String()) == "true"
+
+			upVersions := []uint64{}
+			for _, version := range strings.Split(cmd.Flags().Lookup("up").Value.String(), ",") {
+				if version == "" {
+					continue
+				}
+				v, err := strconv.ParseUint(version, 10, 64)
+				if err != nil {
+					return fmt.Errorf("failed to parse version: %w", err)
+				}
+				upVersions = append(upVersions, v)
+			}
+
+			downVersions := []uint64{}
+			for _, version := range strings.Split(cmd.Flags().Lookup("down").Value.String(), ",") {
+				if version == "" {
+					continue
+				}
+				v, err := strconv.ParseUint(version, 10, 64)
+				if err != nil {
+					return fmt.Errorf("failed to parse version: %w", err)
+				}
+				downVersions = append(downVersions, v)
+			}
+
+			mode := cmd.Flags().Lookup("tls-mode").Value.String()
+			certDir := cmd.Flags().Lookup("cert-dir").Value.String()
+			certName := cmd.Flags().Lookup("cert-name").Value.String()
+			keyName := cmd.Flags().Lookup("key-name").Value.String()
+			caName := cmd.Flags().Lookup("ca-name").Value.String()
+			autoCerts := strings.ToLower(cmd.Flags().Lookup("auto-certs").Value.String()) == "true"
+			acmeDirectory := cmd.Flags().Lookup("acme-directory").Value.String()
+			acmeEABKeyID := cmd.Flags().Lookup("acme-eab-kid").Value.String()
+			acmeEABHMAC := cmd.Flags().Lookup("acme-eab-hmac").Value.String()
+			acmeIdentifier := cmd.Flags().Lookup("acme-identifier").Value.String()
+			asJSON := strings.ToLower(cmd.Flags().Lookup("json").Value.String()) == "true"
+
+			return RunPlanMigrate(&runSyncMigrateArgs{
+				dsn:                dsn,
+				clusterName:        clusterName,
+				replicationEnabled: replicationEnabled,
+				development:        development,
+				upVersions:         upVersions,
+				downVersions:       downVersions,
+				tls: tlsConfigArgs{
+					mode:      tlsMode(mode),
+					certDir:   certDir,
+					certName:  certName,
+					keyName:   keyName,
+					caName:    caName,
+					autoCerts: autoCerts,
+				},
+				acme: acmeConfigArgs{
+					directory:  acmeDirectory,
+					eabKeyID:   acmeEABKeyID,
+					eabHMACKey: acmeEABHMAC,
+					identifier: acmeIdentifier,
+				},
+			}, asJSON)
+		},
+	}
+
+	planCmd.Flags().StringVar(&upVersions, "up", "", "Up migrations to include in the plan, comma separated. Leave empty to consider all up migrations")
+	planCmd.Flags().StringVar(&downVersions, "down", "", "Down migrations to include in the plan, comma separated")
+	planCmd.Flags().StringVar(&mode, "tls-mode", string(tlsModeMTLS), "TLS mode: disabled, system, skip-verify, client, mtls")
+	planCmd.Flags().StringVar(&certDir, "cert-dir", "", "Directory containing the TLS certificate, key, and CA files")
+	planCmd.Flags().StringVar(&certName, "cert-name", "fullchain.crt", "Client certificate file name, relative to cert-dir")
+	planCmd.Flags().StringVar(&keyName, "key-name", "private_migration.key", "Client private key file name, relative to cert-dir")
+	planCmd.Flags().StringVar(&caName, "ca-name", "partialchain.crt", "CA certificate file name, relative to cert-dir")
+	planCmd.Flags().BoolVar(&autoCerts, "auto-certs", false, "Generate an ephemeral self-signed CA/cert in memory when client/mtls mode has no cert files, for local development")
+	planCmd.Flags().StringVar(&acmeDirectory, "acme-directory", "", "ACME server directory URL (e.g. step-ca) to obtain an ephemeral client certificate from, instead of reading cert-name/key-name from disk")
+	planCmd.Flags().StringVar(&acmeEABKeyID, "acme-eab-kid", "", "External Account Binding key ID, required by private ACME servers such as step-ca")
+	planCmd.Flags().StringVar(&acmeEABHMAC, "acme-eab-hmac", "", "External Account Binding HMAC key, base64url-encoded, paired with acme-eab-kid")
+	planCmd.Flags().StringVar(&acmeIdentifier, "acme-identifier", "", "Identifier to request the ACME certificate for, used as the cert's CommonName/SAN")
+	planCmd.Flags().BoolVar(&asJSON, "json", false, "Print the plan as JSON instead of a human-readable table")
+
+	cmd.AddCommand(planCmd)
+}
+
 func registerAsyncMigrate(cmd *cobra.Command) {
 
 	var upVersions string
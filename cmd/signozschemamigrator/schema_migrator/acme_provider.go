@@ -0,0 +1,231 @@
+package schemamigrator
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// ACMEProvider obtains a short-lived client certificate from an ACME server (RFC 8555) --
+// including internal CAs like step-ca that require External Account Binding (EAB) -- instead of
+// reading a long-lived cert/key pair from disk. The issued certificate and its key are cached in
+// memory only; nothing is ever written to disk, and Discard wipes them once a migration is done.
+//
+// Only the tls-alpn-01 challenge is implemented. step-ca's device-attest-01 extension requires a
+// platform attestation statement this provider has no way to produce, so authorizations that
+// don't also offer tls-alpn-01 fail with a clear error rather than silently falling back.
+type ACMEProvider struct {
+	// DirectoryURL is the ACME server's directory endpoint, e.g.
+	// https://step-ca.internal/acme/migrator/directory.
+	DirectoryURL string
+	// Identifier is the DNS identifier the certificate is requested for, and the name ClickHouse
+	// will see on the client cert's CommonName/SAN.
+	Identifier string
+	// EABKeyID/EABHMACKey are the External Account Binding credentials issued out of band by a
+	// private CA such as step-ca. Leave both empty for public CAs that don't require EAB.
+	EABKeyID   string
+	EABHMACKey string // base64url-encoded, as issued by the CA operator
+	// ListenAddr is where the temporary tls-alpn-01 challenge responder listens while the ACME
+	// server validates ownership of Identifier. Defaults to ":443".
+	ListenAddr string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// NotAfter returns the expiry of the currently cached certificate, so the hot-reload subsystem
+// (see tls_reload.go) can track it. The zero Time is returned before Obtain first succeeds.
+func (p *ACMEProvider) NotAfter() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.cert == nil || p.cert.Leaf == nil {
+		return time.Time{}
+	}
+	return p.cert.Leaf.NotAfter
+}
+
+// acmeRenewBefore is how long before the cached certificate's NotAfter Obtain stops returning it
+// and acquires a fresh one instead, so a periodic refresh replaces it before it lapses.
+const acmeRenewBefore = 5 * time.Minute
+
+// Obtain returns the cached certificate if it's valid for at least acmeRenewBefore longer, or
+// acquires a fresh one from the ACME server via the tls-alpn-01 challenge.
+func (p *ACMEProvider) Obtain(ctx context.Context) (*tls.Certificate, error) {
+	p.mu.RLock()
+	cached := p.cert
+	p.mu.RUnlock()
+	if cached != nil && cached.Leaf != nil && time.Now().Add(acmeRenewBefore).Before(cached.Leaf.NotAfter) {
+		return cached, nil
+	}
+
+	cert, err := p.obtainFresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.cert = cert
+	p.mu.Unlock()
+	return cert, nil
+}
+
+// Discard drops the cached certificate and best-effort zeroes the private key material, for
+// callers that want the ephemeral identity gone from memory as soon as the migration finishes.
+func (p *ACMEProvider) Discard() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cert != nil {
+		if key, ok := p.cert.PrivateKey.(*ecdsa.PrivateKey); ok {
+			key.D.SetInt64(0)
+		}
+	}
+	p.cert = nil
+}
+
+func (p *ACMEProvider) obtainFresh(ctx context.Context) (*tls.Certificate, error) {
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ACME account key: %w", err)
+	}
+
+	client := &acme.Client{Key: accountKey, DirectoryURL: p.DirectoryURL}
+
+	account := &acme.Account{}
+	if p.EABKeyID != "" {
+		hmacKey, err := base64.RawURLEncoding.DecodeString(p.EABHMACKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACME EAB hmac key: %w", err)
+		}
+		account.ExternalAccountBinding = &acme.ExternalAccountBinding{KID: p.EABKeyID, Key: hmacKey}
+	}
+
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil {
+		return nil, fmt.Errorf("failed to register ACME account: %w", err)
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(p.Identifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ACME order for %s: %w", p.Identifier, err)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := p.completeAuthorization(ctx, client, authzURL); err != nil {
+			return nil, err
+		}
+	}
+
+	csrDER, leafKey, err := newCertificateRequest(p.Identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ACME CSR: %w", err)
+	}
+
+	derChain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csrDER, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to finalize ACME order: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(derChain[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issued certificate: %w", err)
+	}
+
+	return &tls.Certificate{
+		Certificate: derChain,
+		PrivateKey:  leafKey,
+		Leaf:        leaf,
+	}, nil
+}
+
+// completeAuthorization drives a single authorization's tls-alpn-01 challenge to completion: it
+// stands up a short-lived TLS listener presenting the challenge certificate, tells the ACME
+// server to validate it, and waits for the authorization to become valid.
+func (p *ACMEProvider) completeAuthorization(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch ACME authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "tls-alpn-01" {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("ACME server offered no tls-alpn-01 challenge for %s (device-attest-01 is not supported by this provider)", authz.Identifier.Value)
+	}
+
+	challengeCert, err := client.TLSALPN01ChallengeCert(chal.Token, authz.Identifier.Value)
+	if err != nil {
+		return fmt.Errorf("failed to build tls-alpn-01 challenge cert: %w", err)
+	}
+
+	listenAddr := p.ListenAddr
+	if listenAddr == "" {
+		listenAddr = ":443"
+	}
+	ln, err := tls.Listen("tcp", listenAddr, &tls.Config{
+		Certificates: []tls.Certificate{challengeCert},
+		NextProtos:   []string{"acme-tls/1"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to listen for tls-alpn-01 challenge on %s: %w", listenAddr, err)
+	}
+	defer ln.Close()
+
+	go serveChallengeConns(ln)
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return fmt.Errorf("failed to accept ACME challenge: %w", err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authzURL); err != nil {
+		return fmt.Errorf("ACME authorization for %s did not complete: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+// serveChallengeConns accepts and immediately closes connections on the tls-alpn-01 listener. The
+// challenge is satisfied entirely by the TLS handshake (the acme-tls/1 ALPN protocol and the
+// self-signed certificate carrying the challenge extension), so nothing is read or written.
+func serveChallengeConns(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}
+}
+
+func newCertificateRequest(identifier string) ([]byte, crypto.Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: identifier},
+		DNSNames: []string{identifier},
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return csr, key, nil
+}
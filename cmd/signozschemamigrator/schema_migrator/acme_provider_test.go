@@ -0,0 +1,51 @@
+package schemamigrator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACMEProviderNotAfter(t *testing.T) {
+	p := &ACMEProvider{}
+	assert.True(t, p.NotAfter().IsZero(), "NotAfter must be zero before Obtain ever succeeds")
+
+	notAfter := time.Now().Add(24 * time.Hour)
+	p.cert = &tls.Certificate{Leaf: &x509.Certificate{NotAfter: notAfter}}
+	assert.Equal(t, notAfter, p.NotAfter())
+}
+
+func TestACMEProviderDiscard(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p := &ACMEProvider{cert: &tls.Certificate{
+		PrivateKey: key,
+		Leaf:       &x509.Certificate{NotAfter: time.Now().Add(24 * time.Hour)},
+	}}
+
+	p.Discard()
+
+	assert.True(t, p.NotAfter().IsZero(), "Discard must drop the cached certificate")
+	assert.Zero(t, key.D.Sign(), "Discard must zero the private key's D value")
+}
+
+func TestNewCertificateRequest(t *testing.T) {
+	csrDER, key, err := newCertificateRequest("clickhouse-migrator.internal")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	require.NoError(t, err)
+	require.NoError(t, csr.CheckSignature())
+
+	assert.Equal(t, "clickhouse-migrator.internal", csr.Subject.CommonName)
+	assert.Equal(t, []string{"clickhouse-migrator.internal"}, csr.DNSNames)
+}
@@ -0,0 +1,193 @@
+package schemamigrator
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// KeyProvider supplies the crypto.Signer backing the migrator's client certificate's private key,
+// decoupling where that key lives from the cert/CA files on CertDir. The public certificate chain
+// is still read from CertName as before; only the private key is sourced from the provider.
+type KeyProvider interface {
+	// Signer returns the crypto.Signer to use for the TLS client certificate's private key.
+	Signer(ctx context.Context) (crypto.Signer, error)
+}
+
+// ParseKeyURI selects a KeyProvider from args.KeyURI. Supported schemes:
+//
+//	file:///etc/clickhouse/private_migration.key
+//	pkcs11:token=clickhouse;object=migrator?pin-value=1234
+//	awskms:///alias/migrator
+//	cloudkms://projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1
+//	azurekms://my-vault.vault.azure.net/keys/migrator
+//	ssh-agent:///run/ssh-agent.sock?fingerprint=SHA256:...
+//
+// An empty KeyURI falls back to a file provider reading CertDir/KeyName, preserving the
+// pre-KeyURI behavior.
+func ParseKeyURI(args *MigrateArgs) (KeyProvider, error) {
+	if args.KeyURI == "" {
+		return &fileKeyProvider{path: fmt.Sprintf("%s/%s", args.TLS.CertDir, args.TLS.KeyName)}, nil
+	}
+
+	scheme, rest, ok := strings.Cut(args.KeyURI, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid key uri %q: missing scheme", args.KeyURI)
+	}
+
+	switch scheme {
+	case "file":
+		u, err := url.Parse(args.KeyURI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file key uri %q: %w", args.KeyURI, err)
+		}
+		return &fileKeyProvider{path: u.Path}, nil
+	case "pkcs11":
+		return newPKCS11KeyProvider(rest)
+	case "awskms":
+		return newCloudKMSKeyProvider(cloudKMSVendorAWS, args.KeyURI)
+	case "cloudkms":
+		return newCloudKMSKeyProvider(cloudKMSVendorGCP, args.KeyURI)
+	case "azurekms":
+		return newCloudKMSKeyProvider(cloudKMSVendorAzure, args.KeyURI)
+	case "ssh-agent":
+		return newSSHAgentKeyProvider(args.KeyURI)
+	default:
+		return nil, fmt.Errorf("unsupported key uri scheme %q", scheme)
+	}
+}
+
+// fileKeyProvider reads a PEM-encoded private key from disk, the behavior used before KeyURI was
+// introduced.
+type fileKeyProvider struct {
+	path string
+}
+
+func (p *fileKeyProvider) Signer(_ context.Context) (crypto.Signer, error) {
+	raw, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key %s: %w", p.path, err)
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", p.path)
+	}
+
+	signer, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", p.path, err)
+	}
+	return signer, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("pkcs8 key does not implement crypto.Signer")
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unrecognized private key encoding")
+}
+
+// loadCertificateChain reads the PEM certificate chain from CertName, without touching the
+// private key, for use alongside a KeyProvider-backed signer.
+func loadCertificateChain(cfg TLSConfig) ([][]byte, *x509.Certificate, error) {
+	certFile := fmt.Sprintf("%s/%s", cfg.CertDir, cfg.CertName)
+	raw, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read certificate %s: %w", certFile, err)
+	}
+
+	var chain [][]byte
+	rest := raw
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			chain = append(chain, block.Bytes)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("no certificates found in %s", certFile)
+	}
+
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse leaf certificate %s: %w", certFile, err)
+	}
+	return chain, leaf, nil
+}
+
+// loadKeyProviderCertificate builds a tls.Certificate from the cert chain on disk and a signer
+// sourced from args' KeyProvider, for use in place of tls.LoadX509KeyPair when KeyURI is set.
+func loadKeyProviderCertificate(ctx context.Context, args *MigrateArgs) (*tls.Certificate, error) {
+	provider, err := ParseKeyURI(args)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := provider.Signer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signer from key provider: %w", err)
+	}
+
+	chain, leaf, err := loadCertificateChain(args.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: chain,
+		PrivateKey:  signer,
+		Leaf:        leaf,
+	}, nil
+}
+
+// ValidateKeyProvider resolves args' KeyProvider and performs a test signature, so a
+// misconfigured or unreachable key (wrong PIN, token offline, missing IAM permissions, ...)
+// surfaces before Bootstrap rather than mid-migration.
+func (tm *TLSMigrator) ValidateKeyProvider(args *MigrateArgs) error {
+	provider, err := ParseKeyURI(args)
+	if err != nil {
+		return fmt.Errorf("failed to resolve key provider: %w", err)
+	}
+
+	ctx := context.Background()
+	signer, err := provider.Signer(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get signer from key provider: %w", err)
+	}
+	// A test signature is the only use this signer gets; release any session/handle it holds
+	// (e.g. a pkcs11Signer's token session) rather than leaking it for the rest of the process.
+	if closer, ok := signer.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	digest := make([]byte, 32) // a dummy SHA-256-sized digest; content is irrelevant for a test sign
+	if _, err := signer.Sign(rand.Reader, digest, crypto.SHA256); err != nil {
+		return fmt.Errorf("test signature with key provider failed: %w", err)
+	}
+
+	tm.logger.Info("Key provider validation successful")
+	return nil
+}
@@ -0,0 +1,89 @@
+package schemamigrator
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// cloudKMSVendor identifies which cloud KMS a cloudkms-style key URI targets. The vendor is only
+// used for error messages; the actual API calls are delegated to the registered CloudKMSClient.
+type cloudKMSVendor string
+
+const (
+	cloudKMSVendorGCP   cloudKMSVendor = "gcp cloud kms"
+	cloudKMSVendorAWS   cloudKMSVendor = "aws kms"
+	cloudKMSVendorAzure cloudKMSVendor = "azure key vault"
+)
+
+// CloudKMSClient is the minimal surface this package needs from a cloud KMS SDK client. Callers
+// inject an already-authenticated GCP Cloud KMS / AWS KMS / Azure Key Vault client that implements
+// this interface via RegisterCloudKMSClient, so this package doesn't need to depend on any
+// particular cloud SDK.
+type CloudKMSClient interface {
+	// Public returns the public key of the remote key, used for the crypto.Signer contract. It is
+	// not compared against the certificate loaded from CertName.
+	Public(ctx context.Context) (crypto.PublicKey, error)
+	// Sign signs digest (already hashed per opts) and returns the raw signature bytes.
+	Sign(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+var (
+	cloudKMSClientsMu sync.RWMutex
+	cloudKMSClients   = map[string]CloudKMSClient{}
+)
+
+// RegisterCloudKMSClient associates an authenticated CloudKMSClient with a key URI (the same
+// value passed as MigrateArgs.KeyURI), so ParseKeyURI can resolve
+// "awskms://", "cloudkms://", and "azurekms://" URIs end to end without this package taking a
+// direct dependency on any cloud SDK. Call this during process startup, before RunSyncMigrate.
+func RegisterCloudKMSClient(keyURI string, client CloudKMSClient) {
+	cloudKMSClientsMu.Lock()
+	defer cloudKMSClientsMu.Unlock()
+	cloudKMSClients[keyURI] = client
+}
+
+type cloudKMSKeyProvider struct {
+	vendor cloudKMSVendor
+	keyURI string
+	client CloudKMSClient
+}
+
+func newCloudKMSKeyProvider(vendor cloudKMSVendor, keyURI string) (*cloudKMSKeyProvider, error) {
+	cloudKMSClientsMu.RLock()
+	client, ok := cloudKMSClients[keyURI]
+	cloudKMSClientsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no %s client registered for key uri %q; call RegisterCloudKMSClient with an authenticated client before running migrations", vendor, keyURI)
+	}
+	return &cloudKMSKeyProvider{vendor: vendor, keyURI: keyURI, client: client}, nil
+}
+
+func (p *cloudKMSKeyProvider) Signer(ctx context.Context) (crypto.Signer, error) {
+	if _, err := p.client.Public(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch public key from %s: %w", p.vendor, err)
+	}
+	return &cloudKMSSigner{ctx: ctx, client: p.client}, nil
+}
+
+// cloudKMSSigner adapts a CloudKMSClient to crypto.Signer. The context used for Sign/Public calls
+// is fixed at construction time (from Signer(ctx)) since crypto.Signer's own methods carry no
+// context parameter.
+type cloudKMSSigner struct {
+	ctx    context.Context
+	client CloudKMSClient
+}
+
+func (s *cloudKMSSigner) Public() crypto.PublicKey {
+	pub, err := s.client.Public(s.ctx)
+	if err != nil {
+		return nil
+	}
+	return pub
+}
+
+func (s *cloudKMSSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.client.Sign(s.ctx, digest, opts)
+}
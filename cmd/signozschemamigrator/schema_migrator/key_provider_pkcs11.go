@@ -0,0 +1,309 @@
+package schemamigrator
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+)
+
+// pkcs11KeyProvider sources the client private key from a PKCS#11 token (an HSM or smartcard),
+// identified by a pkcs11 URI of the form "token=<label>;object=<label>[;module-path=<path>]?pin-value=<pin>".
+// module-path can also be supplied via the PKCS11_MODULE environment variable.
+type pkcs11KeyProvider struct {
+	modulePath  string
+	tokenLabel  string
+	objectLabel string
+	pin         string
+}
+
+func newPKCS11KeyProvider(opaque string) (*pkcs11KeyProvider, error) {
+	path, query, _ := strings.Cut(opaque, "?")
+
+	p := &pkcs11KeyProvider{modulePath: os.Getenv("PKCS11_MODULE")}
+	for _, segment := range strings.Split(path, ";") {
+		key, value, ok := strings.Cut(segment, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "token":
+			p.tokenLabel = value
+		case "object":
+			p.objectLabel = value
+		case "module-path":
+			p.modulePath = value
+		}
+	}
+
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkcs11 key uri query %q: %w", query, err)
+		}
+		p.pin = values.Get("pin-value")
+	}
+
+	if p.tokenLabel == "" || p.objectLabel == "" {
+		return nil, fmt.Errorf("pkcs11 key uri must specify both token and object")
+	}
+	if p.modulePath == "" {
+		return nil, fmt.Errorf("pkcs11 key uri requires module-path or the PKCS11_MODULE environment variable")
+	}
+
+	return p, nil
+}
+
+func (p *pkcs11KeyProvider) Signer(_ context.Context) (crypto.Signer, error) {
+	ctx := pkcs11.New(p.modulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load pkcs11 module %s", p.modulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize pkcs11 module %s: %w", p.modulePath, err)
+	}
+
+	session, found, err := p.openSession(ctx)
+	if err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+	if !found {
+		ctx.Destroy()
+		return nil, fmt.Errorf("pkcs11 token %q not found", p.tokenLabel)
+	}
+
+	if p.pin != "" {
+		if err := ctx.Login(session, pkcs11.CKU_USER, p.pin); err != nil {
+			ctx.CloseSession(session)
+			ctx.Destroy()
+			return nil, fmt.Errorf("failed to login to pkcs11 token %q: %w", p.tokenLabel, err)
+		}
+	}
+
+	privHandle, err := p.findObject(ctx, session, pkcs11.CKO_PRIVATE_KEY)
+	if err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("private key object %q not found on token %q: %w", p.objectLabel, p.tokenLabel, err)
+	}
+
+	pub, keyType, err := p.loadPublicKey(ctx, session)
+	if err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to load public key for %q: %w", p.objectLabel, err)
+	}
+
+	return &pkcs11Signer{ctx: ctx, session: session, handle: privHandle, public: pub, keyType: keyType}, nil
+}
+
+func (p *pkcs11KeyProvider) openSession(ctx *pkcs11.Ctx) (pkcs11.SessionHandle, bool, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to list pkcs11 slots: %w", err)
+	}
+
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil || strings.TrimRight(info.Label, "\x00 ") != p.tokenLabel {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to open pkcs11 session: %w", err)
+		}
+		return session, true, nil
+	}
+
+	return 0, false, nil
+}
+
+func (p *pkcs11KeyProvider) findObject(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, p.objectLabel),
+	}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, fmt.Errorf("failed to init object search: %w", err)
+	}
+	defer ctx.FindObjectsFinal(session)
+
+	objs, _, err := ctx.FindObjects(session, 1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find objects: %w", err)
+	}
+	if len(objs) == 0 {
+		return 0, fmt.Errorf("no object found")
+	}
+	return objs[0], nil
+}
+
+// loadPublicKey reads the public key paired with objectLabel so the returned crypto.Signer can
+// satisfy crypto.Signer.Public() without a round trip to the token on every call.
+func (p *pkcs11KeyProvider) loadPublicKey(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) (crypto.PublicKey, uint, error) {
+	handle, err := p.findObject(ctx, session, pkcs11.CKO_PUBLIC_KEY)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	attrs, err := ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_MODULUS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_PUBLIC_EXPONENT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read public key attributes: %w", err)
+	}
+
+	keyType := new(big.Int).SetBytes(attrs[0].Value).Uint64()
+	switch keyType {
+	case pkcs11.CKK_RSA:
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(attrs[1].Value),
+			E: int(new(big.Int).SetBytes(attrs[2].Value).Int64()),
+		}, pkcs11.CKK_RSA, nil
+	case pkcs11.CKK_EC:
+		curve, err := ecParamsToCurve(attrs[4].Value)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// CKA_EC_POINT is a DER OCTET STRING wrapping the raw uncompressed point, not the raw
+		// point itself.
+		var point []byte
+		if _, err := asn1.Unmarshal(attrs[3].Value, &point); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode EC point OCTET STRING: %w", err)
+		}
+
+		x, y := elliptic.Unmarshal(curve, point)
+		if x == nil {
+			return nil, 0, fmt.Errorf("failed to decode EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, pkcs11.CKK_EC, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported pkcs11 key type %d", keyType)
+	}
+}
+
+// ecNamedCurves maps the ASN.1 OIDs PKCS#11 returns in CKA_EC_PARAMS (a DER-encoded namedCurve)
+// to the corresponding crypto/elliptic curve, mirroring the OIDs crypto/x509 recognizes for EC
+// public keys.
+var ecNamedCurves = map[string]elliptic.Curve{
+	"1.2.840.10045.3.1.7": elliptic.P256(),
+	"1.3.132.0.34":        elliptic.P384(),
+	"1.3.132.0.35":        elliptic.P521(),
+}
+
+// ecParamsToCurve decodes a CKA_EC_PARAMS attribute (a DER-encoded namedCurve OID) into the
+// curve it identifies, so keys on tokens using P384/P521 aren't silently mis-parsed as P256.
+func ecParamsToCurve(params []byte) (elliptic.Curve, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("failed to decode EC params: %w", err)
+	}
+
+	curve, ok := ecNamedCurves[oid.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported pkcs11 ec curve oid %s", oid.String())
+	}
+	return curve, nil
+}
+
+// pkcs11Signer implements crypto.Signer against a private key handle held open on a PKCS#11
+// session for the lifetime of a migration run.
+type pkcs11Signer struct {
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	handle  pkcs11.ObjectHandle
+	public  crypto.PublicKey
+	keyType uint
+}
+
+func (s *pkcs11Signer) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Close logs out of and closes the PKCS#11 session opened by Signer and unloads the module,
+// releasing the token/session for the rest of the process lifetime. Callers that only need a
+// short-lived signer (e.g. ValidateKeyProvider's test signature) should call this once done;
+// a signer backing a live TLS connection should stay open for the connection's lifetime instead.
+func (s *pkcs11Signer) Close() error {
+	s.ctx.Logout(s.session)
+	if err := s.ctx.CloseSession(s.session); err != nil {
+		s.ctx.Destroy()
+		return fmt.Errorf("failed to close pkcs11 session: %w", err)
+	}
+	s.ctx.Destroy()
+	return nil
+}
+
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	switch s.keyType {
+	case pkcs11.CKK_RSA:
+		mechanism, prefixed, err := rsaSignMechanism(digest, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(mechanism, nil)}, s.handle); err != nil {
+			return nil, fmt.Errorf("pkcs11 SignInit failed: %w", err)
+		}
+		return s.ctx.Sign(s.session, prefixed)
+	case pkcs11.CKK_EC:
+		if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, s.handle); err != nil {
+			return nil, fmt.Errorf("pkcs11 SignInit failed: %w", err)
+		}
+		raw, err := s.ctx.Sign(s.session, digest)
+		if err != nil {
+			return nil, err
+		}
+		return ecdsaRawToASN1(raw)
+	default:
+		return nil, fmt.Errorf("unsupported pkcs11 key type %d", s.keyType)
+	}
+}
+
+// rsaSignMechanism picks CKM_RSA_PKCS (with the DigestInfo prefix PKCS#1 v1.5 requires) or
+// CKM_RSA_PKCS_PSS depending on opts, mirroring what crypto/rsa would do locally.
+func rsaSignMechanism(digest []byte, opts crypto.SignerOpts) (uint, []byte, error) {
+	if _, ok := opts.(*rsa.PSSOptions); ok {
+		return pkcs11.CKM_RSA_PKCS_PSS, digest, nil
+	}
+
+	prefix, ok := hashPrefixes[opts.HashFunc()]
+	if !ok {
+		return 0, nil, fmt.Errorf("unsupported hash %v for pkcs11 RSA signing", opts.HashFunc())
+	}
+	return pkcs11.CKM_RSA_PKCS, append(prefix, digest...), nil
+}
+
+var hashPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// ecdsaRawToASN1 re-encodes the raw (r || s) signature PKCS#11 returns for CKM_ECDSA as the
+// ASN.1 DER sequence crypto/tls expects from an ecdsa.Signer.
+func ecdsaRawToASN1(raw []byte) ([]byte, error) {
+	if len(raw)%2 != 0 {
+		return nil, fmt.Errorf("unexpected pkcs11 ecdsa signature length %d", len(raw))
+	}
+	half := len(raw) / 2
+	r := new(big.Int).SetBytes(raw[:half])
+	s := new(big.Int).SetBytes(raw[half:])
+
+	type ecdsaSignature struct{ R, S *big.Int }
+	return asn1.Marshal(ecdsaSignature{r, s})
+}
@@ -0,0 +1,118 @@
+package schemamigrator
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// sshAgentKeyProvider sources the client private key from a running ssh-agent, identified by a
+// "ssh-agent://[/path/to/socket][?fingerprint=SHA256:...]" URI. The socket path defaults to
+// SSH_AUTH_SOCK; fingerprint selects among multiple loaded identities and defaults to the first.
+type sshAgentKeyProvider struct {
+	socketPath  string
+	fingerprint string
+}
+
+func newSSHAgentKeyProvider(keyURI string) (*sshAgentKeyProvider, error) {
+	u, err := url.Parse(keyURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh-agent key uri %q: %w", keyURI, err)
+	}
+
+	socketPath := os.Getenv("SSH_AUTH_SOCK")
+	if u.Path != "" {
+		socketPath = u.Path
+	}
+	if socketPath == "" {
+		return nil, fmt.Errorf("ssh-agent key uri requires a socket path or SSH_AUTH_SOCK to be set")
+	}
+
+	return &sshAgentKeyProvider{socketPath: socketPath, fingerprint: u.Query().Get("fingerprint")}, nil
+}
+
+func (p *sshAgentKeyProvider) Signer(_ context.Context) (crypto.Signer, error) {
+	conn, err := net.Dial("unix", p.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh-agent at %s: %w", p.socketPath, err)
+	}
+
+	client := agent.NewClient(conn)
+	signers, err := client.Signers()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to list ssh-agent identities: %w", err)
+	}
+	if len(signers) == 0 {
+		conn.Close()
+		return nil, fmt.Errorf("ssh-agent at %s has no identities loaded", p.socketPath)
+	}
+
+	chosen := signers[0]
+	if p.fingerprint != "" {
+		chosen = nil
+		for _, s := range signers {
+			if ssh.FingerprintSHA256(s.PublicKey()) == p.fingerprint {
+				chosen = s
+				break
+			}
+		}
+		if chosen == nil {
+			conn.Close()
+			return nil, fmt.Errorf("no identity with fingerprint %s loaded in ssh-agent at %s", p.fingerprint, p.socketPath)
+		}
+	}
+
+	pub, ok := chosen.PublicKey().(ssh.CryptoPublicKey)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("ssh-agent identity does not expose a usable public key")
+	}
+
+	// The ssh-agent wire protocol (PROTOCOL.agent SS 2.6.2) hashes the message it's asked to sign
+	// before producing the signature. crypto/tls, on the other hand, hands a Signer an
+	// already-hashed digest for every algorithm except Ed25519 (which signs the raw message
+	// itself). Routing that digest through the agent's Sign/SignWithFlags would hash it a second
+	// time and produce a signature ClickHouse's TLS handshake rejects, so only Ed25519 identities
+	// can be used through this provider.
+	if chosen.PublicKey().Type() != ssh.KeyAlgoED25519 {
+		conn.Close()
+		return nil, fmt.Errorf("ssh-agent key provider only supports ed25519 identities (got %q): ssh-agent hashes the message before signing, which double-hashes the digest crypto/tls provides for other algorithms", chosen.PublicKey().Type())
+	}
+
+	return &sshAgentSigner{conn: conn, signer: chosen, public: pub.CryptoPublicKey()}, nil
+}
+
+// sshAgentSigner adapts an ssh.Signer backed by ssh-agent to crypto.Signer, so it can be used as
+// a tls.Certificate's PrivateKey. It only ever signs Ed25519 keys: ssh-agent hashes the data it's
+// given before signing, which matches Ed25519 (crypto/tls passes it the raw message), but would
+// double-hash the already-hashed digest crypto/tls passes for every other algorithm. sshAgentKeyProvider.Signer
+// rejects non-Ed25519 identities before this type is ever constructed.
+type sshAgentSigner struct {
+	conn   net.Conn
+	signer ssh.Signer
+	public crypto.PublicKey
+}
+
+func (s *sshAgentSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+func (s *sshAgentSigner) Sign(rand io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	sig, err := s.signer.Sign(rand, digest)
+	if err != nil {
+		return nil, fmt.Errorf("ssh-agent signing failed: %w", err)
+	}
+
+	if sig.Format != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("unsupported ssh-agent signature format %q", sig.Format)
+	}
+	return sig.Blob, nil
+}
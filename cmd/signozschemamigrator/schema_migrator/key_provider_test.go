@@ -0,0 +1,105 @@
+package schemamigrator
+
+import (
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeyURI(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    *MigrateArgs
+		want    interface{}
+		wantErr string
+	}{
+		{
+			name: "empty-falls-back-to-file-provider",
+			args: &MigrateArgs{TLS: TLSConfig{CertDir: "/certs", KeyName: "private_migration.key"}},
+			want: &fileKeyProvider{path: "/certs/private_migration.key"},
+		},
+		{
+			name: "file-scheme",
+			args: &MigrateArgs{KeyURI: "file:///etc/clickhouse/private_migration.key"},
+			want: &fileKeyProvider{path: "/etc/clickhouse/private_migration.key"},
+		},
+		{
+			name: "pkcs11-scheme",
+			args: &MigrateArgs{KeyURI: "pkcs11:token=clickhouse;object=migrator;module-path=/usr/lib/softhsm/libsofthsm2.so?pin-value=1234"},
+			want: &pkcs11KeyProvider{tokenLabel: "clickhouse", objectLabel: "migrator", modulePath: "/usr/lib/softhsm/libsofthsm2.so", pin: "1234"},
+		},
+		{
+			// No CloudKMSClient is registered for this URI, so resolution fails past the scheme
+			// dispatch.
+			name:    "awskms-scheme-without-registered-client",
+			args:    &MigrateArgs{KeyURI: "awskms:///alias/migrator"},
+			wantErr: "no aws kms client registered",
+		},
+		{
+			name:    "cloudkms-scheme-without-registered-client",
+			args:    &MigrateArgs{KeyURI: "cloudkms://projects/p/locations/l/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"},
+			wantErr: "no gcp cloud kms client registered",
+		},
+		{
+			name: "ssh-agent-scheme",
+			args: &MigrateArgs{KeyURI: "ssh-agent:///run/ssh-agent.sock?fingerprint=SHA256:abc"},
+			want: &sshAgentKeyProvider{socketPath: "/run/ssh-agent.sock", fingerprint: "SHA256:abc"},
+		},
+		{
+			name:    "unsupported-scheme",
+			args:    &MigrateArgs{KeyURI: "ftp://example.com/key"},
+			wantErr: `unsupported key uri scheme "ftp"`,
+		},
+		{
+			name:    "missing-scheme",
+			args:    &MigrateArgs{KeyURI: "not-a-uri"},
+			wantErr: "missing scheme",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseKeyURI(tc.args)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEcdsaRawToASN1(t *testing.T) {
+	r := big.NewInt(12345)
+	s := big.NewInt(67890)
+
+	// PKCS#11 returns fixed-width r||s, each independently left-padded to the
+	// width of the wider of the two.
+	width := len(r.Bytes())
+	if len(s.Bytes()) > width {
+		width = len(s.Bytes())
+	}
+	raw := make([]byte, 2*width)
+	r.FillBytes(raw[width-len(r.Bytes()) : width])
+	s.FillBytes(raw[2*width-len(s.Bytes()):])
+
+	der, err := ecdsaRawToASN1(raw)
+	require.NoError(t, err)
+
+	var decoded struct{ R, S *big.Int }
+	_, err = asn1.Unmarshal(der, &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.Cmp(decoded.R))
+	assert.Equal(t, 0, s.Cmp(decoded.S))
+}
+
+func TestEcdsaRawToASN1OddLength(t *testing.T) {
+	_, err := ecdsaRawToASN1([]byte{0x01, 0x02, 0x03})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected pkcs11 ecdsa signature length")
+}
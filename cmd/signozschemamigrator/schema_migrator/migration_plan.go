@@ -0,0 +1,109 @@
+package schemamigrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ShardVersion is the current applied schema version for a single ClickHouse shard.
+type ShardVersion struct {
+	Shard   string `json:"shard"`
+	Version uint64 `json:"version"`
+}
+
+// PlannedMigration is a migration that Plan determined would run, along with the DDL it executes.
+type PlannedMigration struct {
+	Version uint64   `json:"version"`
+	Name    string   `json:"name"`
+	DDL     []string `json:"ddl"`
+}
+
+// DriftedMigration is a migration recorded as applied against the cluster that this binary has no
+// definition for -- typically because the binary is older than whatever produced the cluster's
+// schema_migrations table, or a migration file was removed.
+type DriftedMigration struct {
+	Shard   string `json:"shard"`
+	Version uint64 `json:"version"`
+}
+
+// MigrationPlan is the structured, read-only result of MigrationManager.Plan: what would happen
+// if the pending up/down migrations were actually run, without executing any DDL.
+type MigrationPlan struct {
+	ShardVersions []ShardVersion     `json:"shardVersions"`
+	PendingUp     []PlannedMigration `json:"pendingUp"`
+	PendingDown   []PlannedMigration `json:"pendingDown"`
+	Drift         []DriftedMigration `json:"drift"`
+}
+
+// HasDrift reports whether the plan found any applied-but-undefined migrations. CI pipelines use
+// this to gate deploys: RunSyncMigrate with DryRun set returns a non-nil error when it's true.
+func (p *MigrationPlan) HasDrift() bool {
+	return len(p.Drift) > 0
+}
+
+// JSON renders the plan as indented JSON, for tooling that wants to diff the plan programmatically.
+func (p *MigrationPlan) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// String renders the plan as the human-readable table printed by `signoz-schema-migrator plan`.
+func (p *MigrationPlan) String() string {
+	var b strings.Builder
+
+	fmt.Fprintln(&b, "Current schema versions:")
+	for _, sv := range p.ShardVersions {
+		fmt.Fprintf(&b, "  %s: v%d\n", sv.Shard, sv.Version)
+	}
+
+	fmt.Fprintln(&b, "Pending up migrations:")
+	if len(p.PendingUp) == 0 {
+		fmt.Fprintln(&b, "  (none)")
+	}
+	for _, m := range p.PendingUp {
+		fmt.Fprintf(&b, "  v%d %s (%d statement(s))\n", m.Version, m.Name, len(m.DDL))
+	}
+
+	fmt.Fprintln(&b, "Pending down migrations:")
+	if len(p.PendingDown) == 0 {
+		fmt.Fprintln(&b, "  (none)")
+	}
+	for _, m := range p.PendingDown {
+		fmt.Fprintf(&b, "  v%d %s (%d statement(s))\n", m.Version, m.Name, len(m.DDL))
+	}
+
+	if len(p.Drift) > 0 {
+		fmt.Fprintln(&b, "Drift (applied on the cluster but missing from this binary):")
+		for _, d := range p.Drift {
+			fmt.Fprintf(&b, "  %s: v%d\n", d.Shard, d.Version)
+		}
+	}
+
+	return b.String()
+}
+
+// Plan computes the set of up and down migrations that would run against the cluster for the
+// given version selectors, and any drift between what's applied and what this binary knows
+// about, without executing any DDL. It is the pure, read-only counterpart to MigrateUpSync/
+// MigrateDownSync: resolvePendingMigrations is the same version-resolution logic both the
+// executor and Plan share, so the plan a caller sees here is exactly what running the migration
+// would do.
+func (m *MigrationManager) Plan(ctx context.Context, upVersions, downVersions []uint64) (*MigrationPlan, error) {
+	shardVersions, err := m.currentShardVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current schema versions: %w", err)
+	}
+
+	pendingUp, pendingDown, drift, err := m.resolvePendingMigrations(ctx, shardVersions, upVersions, downVersions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve pending migrations: %w", err)
+	}
+
+	return &MigrationPlan{
+		ShardVersions: shardVersions,
+		PendingUp:     pendingUp,
+		PendingDown:   pendingDown,
+		Drift:         drift,
+	}, nil
+}
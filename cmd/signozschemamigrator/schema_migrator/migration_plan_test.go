@@ -0,0 +1,49 @@
+package schemamigrator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationPlanHasDrift(t *testing.T) {
+	assert.False(t, (&MigrationPlan{}).HasDrift())
+	assert.True(t, (&MigrationPlan{Drift: []DriftedMigration{{Shard: "shard-1", Version: 3}}}).HasDrift())
+}
+
+func TestMigrationPlanJSON(t *testing.T) {
+	plan := &MigrationPlan{
+		ShardVersions: []ShardVersion{{Shard: "shard-1", Version: 2}},
+		PendingUp:     []PlannedMigration{{Version: 3, Name: "add_column", DDL: []string{"ALTER TABLE ..."}}},
+		Drift:         []DriftedMigration{{Shard: "shard-1", Version: 99}},
+	}
+
+	raw, err := plan.JSON()
+	require.NoError(t, err)
+
+	var decoded MigrationPlan
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, plan, &decoded)
+}
+
+func TestMigrationPlanString(t *testing.T) {
+	plan := &MigrationPlan{
+		ShardVersions: []ShardVersion{{Shard: "shard-1", Version: 2}},
+		PendingUp:     []PlannedMigration{{Version: 3, Name: "add_column", DDL: []string{"ALTER TABLE ...", "ALTER TABLE ..."}}},
+		Drift:         []DriftedMigration{{Shard: "shard-1", Version: 99}},
+	}
+
+	out := plan.String()
+	assert.Contains(t, out, "shard-1: v2")
+	assert.Contains(t, out, "v3 add_column (2 statement(s))")
+	assert.Contains(t, out, "Pending down migrations:\n  (none)")
+	assert.Contains(t, out, "Drift (applied on the cluster but missing from this binary):")
+	assert.Contains(t, out, "shard-1: v99")
+}
+
+func TestMigrationPlanStringOmitsDriftSectionWhenClean(t *testing.T) {
+	out := (&MigrationPlan{}).String()
+	assert.NotContains(t, out, "Drift")
+}
@@ -4,17 +4,55 @@ package schemamigrator
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"log"
+	"math/big"
+	"net"
 	"os"
+	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// TLSMode selects how TLSMigrator secures the connection to ClickHouse.
+type TLSMode string
+
+const (
+	// TLSModeDisabled does not use TLS at all.
+	TLSModeDisabled TLSMode = "disabled"
+	// TLSModeSystem uses the OS trust store to verify the server, without a client certificate.
+	TLSModeSystem TLSMode = "system"
+	// TLSModeSkipVerify connects over TLS but does not verify the server certificate. Not for production use.
+	TLSModeSkipVerify TLSMode = "skip-verify"
+	// TLSModeClient verifies the server against CAName and presents a client certificate only if the
+	// server requests one. CertName/KeyName are optional in this mode.
+	TLSModeClient TLSMode = "client"
+	// TLSModeMTLS requires a client certificate, key, and CA for full mutual TLS.
+	TLSModeMTLS TLSMode = "mtls"
+)
+
+// TLSConfig describes how TLSMigrator should secure the connection to ClickHouse.
+type TLSConfig struct {
+	Mode     TLSMode
+	CertDir  string
+	CertName string
+	KeyName  string
+	CAName   string
+	// AutoCerts generates an ephemeral, in-memory self-signed CA/client certificate when Mode is
+	// TLSModeClient and the CA file cannot be loaded, or TLSModeMTLS and any of the cert/key/CA
+	// files is missing. Intended for local
+	// development and testing against a ClickHouse instance that trusts throwaway certs.
+	AutoCerts bool
+}
+
 // MigrateArgs represents the arguments needed for running sync migrations
 type MigrateArgs struct {
 	DSN                string
@@ -23,15 +61,27 @@ type MigrateArgs struct {
 	Development        bool
 	UpVersions         []uint64
 	DownVersions       []uint64
-	CertDir            string
-	CertName           string
-	KeyName            string
-	CAName             string
+	TLS                TLSConfig
+	// KeyURI, when set, selects a KeyProvider to source the client certificate's private key from
+	// instead of TLS.CertDir/TLS.KeyName, e.g. "pkcs11:token=clickhouse;object=migrator",
+	// "awskms:///alias/migrator", or "file:///etc/clickhouse/private_migration.key".
+	KeyURI string
+	// ACME, when set, obtains an ephemeral client certificate from an ACME server instead of
+	// reading TLS.CertName/TLS.KeyName from disk. Takes priority over KeyURI.
+	ACME *ACMEProvider
+	// DryRun, when true, makes RunSyncMigrate compute and log the migration plan instead of
+	// applying it, returning an error (for CI gating) if the plan detects drift. No DDL is
+	// executed. Equivalent to calling TLSMigrator.PlanMigrations directly.
+	DryRun bool
 }
 
 // TLSMigrator wraps the RunSyncMigrate functionality with additional features
 type TLSMigrator struct {
 	logger *zap.Logger
+
+	// reload holds the hot-reload state when this migrator was created via
+	// NewTLSMigratorWithReload or NewTLSMigratorWithACMEReload. It is nil otherwise.
+	reload *tlsReloader
 }
 
 // NewTLSMigrator creates a new TLS migrator instance
@@ -56,47 +106,227 @@ func getLogger() *zap.Logger {
 	return logger
 }
 
-// createTLSConfig creates a TLS configuration from the provided arguments
+// createTLSConfig creates a TLS configuration from the provided arguments, dispatching on
+// args.TLS.Mode.
 func (tm *TLSMigrator) createTLSConfig(args *MigrateArgs) (*tls.Config, error) {
-	// custom tls config for full mtls enabled clickhouse
-	dir := args.CertDir
-	certName := args.CertName
-	keyName := args.KeyName
-	caName := args.CAName
-	certFile := fmt.Sprintf("%s/%s", dir, certName)
-	privateKeyFile := fmt.Sprintf("%s/%s", dir, keyName)
-	caFile := fmt.Sprintf("%s/%s", dir, caName)
-
-	tm.logger.Info("Loading cert/key",
-		zap.String("cert", certFile),
-		zap.String("key", privateKeyFile))
-	cert, err := tls.LoadX509KeyPair(certFile, privateKeyFile)
+	if tm.reload != nil && args.KeyURI != "" {
+		return nil, fmt.Errorf("hot reload cannot be combined with KeyURI: the reloader only watches cert/key/CA files on disk or refreshes from the ACMEProvider it was created with, so it cannot source a KeyProvider key")
+	}
+	if tm.reload != nil && tm.reload.acme == nil && args.ACME != nil {
+		return nil, fmt.Errorf("hot reload cannot be combined with MigrateArgs.ACME: use NewTLSMigratorWithACMEReload to hot reload an ACME-issued certificate instead")
+	}
+
+	switch args.TLS.Mode {
+	case "", TLSModeDisabled:
+		return nil, nil
+	case TLSModeSystem:
+		tm.logger.Info("Using system trust store for TLS, no client certificate")
+		return &tls.Config{}, nil
+	case TLSModeSkipVerify:
+		tm.logger.Warn("TLS certificate verification is disabled, do not use in production")
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	case TLSModeClient:
+		return tm.createClientTLSConfig(args)
+	case TLSModeMTLS:
+		return tm.createMTLSConfig(args)
+	default:
+		return nil, fmt.Errorf("unknown tls mode: %q", args.TLS.Mode)
+	}
+}
+
+// createClientTLSConfig builds a TLS config that verifies the server against the configured CA
+// and, if a cert/key pair is available, presents it only when the server asks for one.
+func (tm *TLSMigrator) createClientTLSConfig(args *MigrateArgs) (*tls.Config, error) {
+	if tm.reload != nil {
+		serverName, err := serverNameFromDSN(args.DSN)
+		if err != nil {
+			return nil, err
+		}
+		return tm.reload.tlsConfig(serverName), nil
+	}
+
+	caCertPool, err := loadCAPool(args.TLS)
+	if err != nil {
+		if !args.TLS.AutoCerts {
+			return nil, err
+		}
+		tm.logger.Info("No CA found, generating ephemeral dev CA/cert")
+		cert, pool, genErr := generateDevCertificate()
+		if genErr != nil {
+			return nil, fmt.Errorf("failed to generate dev certificate: %w", genErr)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{*cert}, RootCAs: pool}, nil
+	}
+
+	tlsConfig := &tls.Config{RootCAs: caCertPool}
+
+	switch {
+	case args.ACME != nil:
+		cert, err := args.ACME.Obtain(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain ACME certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	case args.KeyURI != "":
+		cert, err := loadKeyProviderCertificate(context.Background(), args)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	case hasFile(args.TLS.CertDir, args.TLS.CertName) && hasFile(args.TLS.CertDir, args.TLS.KeyName):
+		cert, err := loadKeyPair(args.TLS)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// createMTLSConfig builds a TLS config for full mutual TLS: a client certificate, key, and CA are
+// all required, either loaded from disk or, with AutoCerts, generated in memory.
+func (tm *TLSMigrator) createMTLSConfig(args *MigrateArgs) (*tls.Config, error) {
+	if tm.reload != nil {
+		serverName, err := serverNameFromDSN(args.DSN)
+		if err != nil {
+			return nil, err
+		}
+		return tm.reload.tlsConfig(serverName), nil
+	}
+
+	if args.TLS.AutoCerts && !(hasFile(args.TLS.CertDir, args.TLS.CertName) && hasFile(args.TLS.CertDir, args.TLS.KeyName) && hasFile(args.TLS.CertDir, args.TLS.CAName)) {
+		tm.logger.Info("No cert/key/CA found, generating ephemeral dev CA/cert for mtls")
+		cert, pool, err := generateDevCertificate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate dev certificate: %w", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{*cert}, RootCAs: pool}, nil
+	}
+
+	var cert *tls.Certificate
+	var err error
+	switch {
+	case args.ACME != nil:
+		cert, err = args.ACME.Obtain(context.Background())
+	case args.KeyURI != "":
+		cert, err = loadKeyProviderCertificate(context.Background(), args)
+	default:
+		cert, err = loadKeyPair(args.TLS)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPool, err := loadCAPool(args.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		RootCAs:      caCertPool,
+	}, nil
+}
+
+func hasFile(dir, name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := os.Stat(fmt.Sprintf("%s/%s", dir, name))
+	return err == nil
+}
+
+func loadKeyPair(cfg TLSConfig) (*tls.Certificate, error) {
+	certFile := fmt.Sprintf("%s/%s", cfg.CertDir, cfg.CertName)
+	keyFile := fmt.Sprintf("%s/%s", cfg.CertDir, cfg.KeyName)
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load client key pair: %w", err)
 	}
+	return &cert, nil
+}
 
-	tm.logger.Info("Loading CA cert", zap.String("ca", caFile))
+func loadCAPool(cfg TLSConfig) (*x509.CertPool, error) {
+	caFile := fmt.Sprintf("%s/%s", cfg.CertDir, cfg.CAName)
 	caCert, err := os.ReadFile(caFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read ca certificate: %w", err)
 	}
 
-	tm.logger.Info("Creating cert pool")
 	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse ca certificate")
+	}
+	return caCertPool, nil
+}
 
-	tm.logger.Info("Making TLS config")
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caCertPool,
+// generateDevCertificate creates an ephemeral, in-memory self-signed CA and a client certificate
+// signed by it, for use with auto-certs against a test ClickHouse instance. Nothing is written to
+// disk.
+func generateDevCertificate() (*tls.Certificate, *x509.CertPool, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate ca key: %w", err)
 	}
 
-	tm.logger.Info("TLS config created successfully")
-	return tlsConfig, nil
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signoz-schema-migrator dev CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create ca certificate: %w", err)
+	}
+
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse generated ca certificate: %w", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate client key: %w", err)
+	}
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "signoz-schema-migrator dev client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client certificate: %w", err)
+	}
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(caCert)
+
+	return &tls.Certificate{
+		Certificate: [][]byte{leafDER},
+		PrivateKey:  leafKey,
+	}, caPool, nil
 }
 
-// RunSyncMigrate wraps the original RunSyncMigrate function with additional logging and error handling
+// RunSyncMigrate wraps the original RunSyncMigrate function with additional logging and error handling.
+// When args.ACME is set, this single call bootstraps the migrator's identity, obtains its client
+// certificate from the ACME server, runs the migrations, and discards the certificate's key
+// material before returning, regardless of outcome.
 func (tm *TLSMigrator) RunSyncMigrate(args *MigrateArgs) error {
+	if args.ACME != nil {
+		defer args.ACME.Discard()
+	}
+
 	tm.logger.Info("Starting TLS migrator",
 		zap.String("dsn", args.DSN),
 		zap.Bool("replication", args.ReplicationEnabled),
@@ -106,15 +336,97 @@ func (tm *TLSMigrator) RunSyncMigrate(args *MigrateArgs) error {
 		return fmt.Errorf("cannot provide both up and down migrations")
 	}
 
+	manager, err := tm.openMigrationManager(args)
+	if err != nil {
+		return err
+	}
+
+	if args.DryRun {
+		plan, err := manager.Plan(context.Background(), args.UpVersions, args.DownVersions)
+		if err != nil {
+			return fmt.Errorf("failed to compute migration plan: %w", err)
+		}
+		tm.logger.Info("Dry run complete, no DDL executed", zap.String("plan", plan.String()))
+		if plan.HasDrift() {
+			return fmt.Errorf("migration plan detected %d drifted migration(s): applied on the cluster but missing from this binary", len(plan.Drift))
+		}
+		return nil
+	}
+
+	err = manager.Bootstrap()
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap migrations: %w", err)
+	}
+	tm.logger.Info("Bootstrapped migrations")
+
+	err = manager.RunSquashedMigrations(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to run squashed migrations: %w", err)
+	}
+	tm.logger.Info("Ran squashed migrations")
+
+	if len(args.DownVersions) != 0 {
+		tm.logger.Info("Migrating down")
+		return manager.MigrateDownSync(context.Background(), args.DownVersions)
+	}
+	tm.logger.Info("Migrating up")
+	return manager.MigrateUpSync(context.Background(), args.UpVersions)
+}
+
+// PlanMigrations computes the migrations that RunSyncMigrate would apply for args -- pending up/
+// down versions with their DDL, current schema versions per shard, and any drift -- without
+// executing any DDL. It shares its connection setup and manager construction with RunSyncMigrate
+// via openMigrationManager, so the plan reflects exactly what a real run would do.
+func (tm *TLSMigrator) PlanMigrations(args *MigrateArgs) (*MigrationPlan, error) {
+	if args.ACME != nil {
+		defer args.ACME.Discard()
+	}
+
+	if len(args.UpVersions) != 0 && len(args.DownVersions) != 0 {
+		return nil, fmt.Errorf("cannot provide both up and down migrations")
+	}
+
+	manager, err := tm.openMigrationManager(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return manager.Plan(context.Background(), args.UpVersions, args.DownVersions)
+}
+
+// serverNameFromDSN extracts the host ClickHouse will be dialed at from dsn, for use as the
+// expected server identity when hot reload's VerifyPeerCertificate has to re-implement the
+// hostname check that tls.Config.ServerName normally gets for free from the dialer.
+func serverNameFromDSN(dsn string) (string, error) {
+	opts, err := clickhouse.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse dsn: %w", err)
+	}
+	if len(opts.Addr) == 0 {
+		return "", fmt.Errorf("dsn has no host")
+	}
+	host, _, err := net.SplitHostPort(opts.Addr[0])
+	if err != nil {
+		// no port present, the whole thing is the host
+		return opts.Addr[0], nil
+	}
+	return host, nil
+}
+
+// openMigrationManager parses the DSN, builds the TLS config, opens the ClickHouse connection,
+// and constructs the MigrationManager described by args. RunSyncMigrate and PlanMigrations share
+// this so a dry-run plan always reflects the exact same connection and cluster configuration the
+// real run would use.
+func (tm *TLSMigrator) openMigrationManager(args *MigrateArgs) (*MigrationManager, error) {
 	opts, err := clickhouse.ParseDSN(args.DSN)
 	if err != nil {
-		return fmt.Errorf("failed to parse dsn: %w", err)
+		return nil, fmt.Errorf("failed to parse dsn: %w", err)
 	}
 	tm.logger.Info("Parsed DSN", zap.Any("opts", opts))
 
 	tlsConfig, err := tm.createTLSConfig(args)
 	if err != nil {
-		return fmt.Errorf("failed to get tls config: %w", err)
+		return nil, fmt.Errorf("failed to get tls config: %w", err)
 	}
 
 	opts.TLS = tlsConfig
@@ -123,7 +435,7 @@ func (tm *TLSMigrator) RunSyncMigrate(args *MigrateArgs) error {
 	tm.logger.Info("Opening connection")
 	conn, err := clickhouse.Open(opts)
 	if err != nil {
-		return fmt.Errorf("failed to open connection: %w", err)
+		return nil, fmt.Errorf("failed to open connection: %w", err)
 	}
 	tm.logger.Info("Opened connection successfully")
 
@@ -136,27 +448,21 @@ func (tm *TLSMigrator) RunSyncMigrate(args *MigrateArgs) error {
 		WithDevelopment(args.Development),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create migration manager: %w", err)
-	}
-
-	err = manager.Bootstrap()
-	if err != nil {
-		return fmt.Errorf("failed to bootstrap migrations: %w", err)
-	}
-	tm.logger.Info("Bootstrapped migrations")
-
-	err = manager.RunSquashedMigrations(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to run squashed migrations: %w", err)
+		return nil, fmt.Errorf("failed to create migration manager: %w", err)
 	}
-	tm.logger.Info("Ran squashed migrations")
+	return manager, nil
+}
 
-	if len(args.DownVersions) != 0 {
-		tm.logger.Info("Migrating down")
-		return manager.MigrateDownSync(context.Background(), args.DownVersions)
+// defaultMTLSConfig builds the mtls TLS profile shared by the RunSyncMigrateWithDefaults/
+// RunUpMigrations/RunDownMigrations shortcuts.
+func defaultMTLSConfig(certDir string) TLSConfig {
+	return TLSConfig{
+		Mode:     TLSModeMTLS,
+		CertDir:  certDir,
+		CertName: "fullchain.crt",
+		KeyName:  "private_migration.key",
+		CAName:   "partialchain.crt",
 	}
-	tm.logger.Info("Migrating up")
-	return manager.MigrateUpSync(context.Background(), args.UpVersions)
 }
 
 // RunSyncMigrateWithDefaults runs the migration with commonly used default values
@@ -168,10 +474,7 @@ func (tm *TLSMigrator) RunSyncMigrateWithDefaults(dsn, clusterName, certDir stri
 		Development:        false,
 		UpVersions:         []uint64{}, // empty means run all
 		DownVersions:       []uint64{},
-		CertDir:            certDir,
-		CertName:           "fullchain.crt",
-		KeyName:            "private_migration.key",
-		CAName:             "partialchain.crt",
+		TLS:                defaultMTLSConfig(certDir),
 	}
 
 	return tm.RunSyncMigrate(args)
@@ -186,10 +489,7 @@ func (tm *TLSMigrator) RunUpMigrations(dsn, clusterName, certDir string, version
 		Development:        false,
 		UpVersions:         versions,
 		DownVersions:       []uint64{},
-		CertDir:            certDir,
-		CertName:           "fullchain.crt",
-		KeyName:            "private_migration.key",
-		CAName:             "partialchain.crt",
+		TLS:                defaultMTLSConfig(certDir),
 	}
 
 	return tm.RunSyncMigrate(args)
@@ -204,54 +504,54 @@ func (tm *TLSMigrator) RunDownMigrations(dsn, clusterName, certDir string, versi
 		Development:        false,
 		UpVersions:         []uint64{},
 		DownVersions:       versions,
-		CertDir:            certDir,
-		CertName:           "fullchain.crt",
-		KeyName:            "private_migration.key",
-		CAName:             "partialchain.crt",
+		TLS:                defaultMTLSConfig(certDir),
 	}
 
 	return tm.RunSyncMigrate(args)
 }
 
-// ValidateTLSConfig validates that the TLS certificate files exist and are readable
+// ValidateTLSConfig validates that the TLS certificate files required by args.TLS.Mode exist and
+// are readable. Modes that don't need files (disabled/system/skip-verify) are accepted outright,
+// and auto-certs modes skip file checks since the certificate is generated in memory.
 func (tm *TLSMigrator) ValidateTLSConfig(args *MigrateArgs) error {
-	certFile := fmt.Sprintf("%s/%s", args.CertDir, args.CertName)
-	keyFile := fmt.Sprintf("%s/%s", args.CertDir, args.KeyName)
-	caFile := fmt.Sprintf("%s/%s", args.CertDir, args.CAName)
-
-	// Check if certificate file exists and is readable
-	if _, err := os.Stat(certFile); err != nil {
-		return fmt.Errorf("certificate file not accessible: %w", err)
-	}
-
-	// Check if key file exists and is readable
-	if _, err := os.Stat(keyFile); err != nil {
-		return fmt.Errorf("key file not accessible: %w", err)
-	}
-
-	// Check if CA file exists and is readable
-	if _, err := os.Stat(caFile); err != nil {
-		return fmt.Errorf("CA file not accessible: %w", err)
-	}
-
-	// Try to load the certificate pair to validate
-	_, err := tls.LoadX509KeyPair(certFile, keyFile)
-	if err != nil {
-		return fmt.Errorf("failed to load certificate pair: %w", err)
-	}
-
-	// Try to read and parse the CA certificate
-	caCert, err := os.ReadFile(caFile)
-	if err != nil {
-		return fmt.Errorf("failed to read CA certificate: %w", err)
-	}
-
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return fmt.Errorf("failed to parse CA certificate")
+	switch args.TLS.Mode {
+	case "", TLSModeDisabled, TLSModeSystem, TLSModeSkipVerify:
+		tm.logger.Info("TLS configuration validation successful", zap.String("mode", string(args.TLS.Mode)))
+		return nil
+	case TLSModeClient:
+		if args.TLS.AutoCerts && !hasFile(args.TLS.CertDir, args.TLS.CAName) {
+			tm.logger.Info("TLS configuration validation successful (auto-certs)")
+			return nil
+		}
+		if _, err := loadCAPool(args.TLS); err != nil {
+			return err
+		}
+		certPresent := hasFile(args.TLS.CertDir, args.TLS.CertName)
+		keyPresent := hasFile(args.TLS.CertDir, args.TLS.KeyName)
+		if certPresent != keyPresent {
+			return fmt.Errorf("client cert and key must both be provided, or both omitted")
+		}
+		if certPresent && keyPresent {
+			if _, err := loadKeyPair(args.TLS); err != nil {
+				return err
+			}
+		}
+	case TLSModeMTLS:
+		if args.TLS.AutoCerts && !(hasFile(args.TLS.CertDir, args.TLS.CertName) && hasFile(args.TLS.CertDir, args.TLS.KeyName) && hasFile(args.TLS.CertDir, args.TLS.CAName)) {
+			tm.logger.Info("TLS configuration validation successful (auto-certs)")
+			return nil
+		}
+		if _, err := loadKeyPair(args.TLS); err != nil {
+			return err
+		}
+		if _, err := loadCAPool(args.TLS); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown tls mode: %q", args.TLS.Mode)
 	}
 
-	tm.logger.Info("TLS configuration validation successful")
+	tm.logger.Info("TLS configuration validation successful", zap.String("mode", string(args.TLS.Mode)))
 	return nil
 }
 
@@ -0,0 +1,87 @@
+package schemamigrator
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDevCertificate(t *testing.T) {
+	cert, pool, err := generateDevCertificate()
+	require.NoError(t, err)
+	require.Len(t, cert.Certificate, 1)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.NoError(t, err, "dev client certificate must chain to the returned CA pool")
+
+	key, ok := cert.PrivateKey.(*ecdsa.PrivateKey)
+	require.True(t, ok, "dev client key must be an ECDSA key")
+	assert.True(t, key.PublicKey.Equal(leaf.PublicKey), "private key must match the leaf certificate's public key")
+}
+
+func TestValidateTLSConfig(t *testing.T) {
+	tm := NewTLSMigrator()
+
+	testCases := []struct {
+		name    string
+		args    *MigrateArgs
+		wantErr string
+	}{
+		{
+			name: "empty-mode-accepted",
+			args: &MigrateArgs{TLS: TLSConfig{}},
+		},
+		{
+			name: "disabled-accepted-without-files",
+			args: &MigrateArgs{TLS: TLSConfig{Mode: TLSModeDisabled}},
+		},
+		{
+			name: "system-accepted-without-files",
+			args: &MigrateArgs{TLS: TLSConfig{Mode: TLSModeSystem}},
+		},
+		{
+			name: "skip-verify-accepted-without-files",
+			args: &MigrateArgs{TLS: TLSConfig{Mode: TLSModeSkipVerify}},
+		},
+		{
+			name: "client-autocerts-skips-file-checks",
+			args: &MigrateArgs{TLS: TLSConfig{Mode: TLSModeClient, CertDir: t.TempDir(), AutoCerts: true}},
+		},
+		{
+			name:    "client-without-autocerts-requires-ca",
+			args:    &MigrateArgs{TLS: TLSConfig{Mode: TLSModeClient, CertDir: t.TempDir(), CAName: "ca.pem"}},
+			wantErr: "failed to read ca certificate",
+		},
+		{
+			name:    "mtls-autocerts-missing-files-still-errors-without-flag",
+			args:    &MigrateArgs{TLS: TLSConfig{Mode: TLSModeMTLS, CertDir: t.TempDir(), CertName: "cert.pem", KeyName: "key.pem"}},
+			wantErr: "failed to load client key pair",
+		},
+		{
+			name:    "unknown-mode-rejected",
+			args:    &MigrateArgs{TLS: TLSConfig{Mode: "bogus"}},
+			wantErr: `unknown tls mode: "bogus"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tm.ValidateTLSConfig(tc.args)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package schemamigrator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// statFallbackInterval is how often the reloader re-checks the cert/CA files on disk even when
+// fsnotify hasn't reported a change, to guard against missed events (e.g. on network mounts).
+const statFallbackInterval = 30 * time.Second
+
+// tlsReloader keeps an atomically-swapped client certificate and CA pool fresh, so a
+// long-running migration can survive the cert issuer rotating short-lived certificates
+// underneath it. The client certificate comes from either CertDir/CertName/KeyName on disk
+// (watched via fsnotify) or, when acme is set, periodic refresh against an ACMEProvider; the CA
+// pool always comes from CertDir/CAName on disk.
+type tlsReloader struct {
+	logger *zap.Logger
+	cfg    TLSConfig
+	acme   *ACMEProvider
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	caPool *x509.CertPool
+
+	watcher *fsnotify.Watcher // nil when acme is set: there's no cert file on disk to watch
+	stopped atomic.Bool
+}
+
+// newTLSReloader loads the initial cert/CA and starts refreshing them for the lifetime of ctx.
+// With acmeProvider nil, the client certificate is re-read from CertDir/CertName/KeyName on an
+// fsnotify watch plus a periodic re-stat fallback; with acmeProvider set, it's refreshed from the
+// provider on that same periodic interval instead (ACMEProvider.Obtain is a no-op until the
+// cached certificate nears expiry). The CA pool is always re-read from CertDir/CAName on disk.
+func newTLSReloader(ctx context.Context, logger *zap.Logger, cfg TLSConfig, acmeProvider *ACMEProvider) (*tlsReloader, error) {
+	if cfg.AutoCerts {
+		return nil, fmt.Errorf("hot reload cannot be combined with AutoCerts: there are no cert/key/CA files on disk to watch")
+	}
+
+	r := &tlsReloader{logger: logger, cfg: cfg, acme: acmeProvider}
+
+	if err := r.reloadCert(); err != nil {
+		return nil, fmt.Errorf("failed to load initial client certificate: %w", err)
+	}
+	if err := r.reloadCA(); err != nil {
+		return nil, fmt.Errorf("failed to load initial ca certificate: %w", err)
+	}
+
+	if acmeProvider == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+		}
+		if err := watcher.Add(cfg.CertDir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch cert dir %s: %w", cfg.CertDir, err)
+		}
+		r.watcher = watcher
+	}
+
+	go r.run(ctx)
+
+	return r, nil
+}
+
+// run drains fsnotify events and the periodic re-stat ticker, reloading on either, until ctx is
+// cancelled. In ACME mode (r.watcher nil) there are no cert files to watch, so it just reloads on
+// the ticker.
+func (r *tlsReloader) run(ctx context.Context) {
+	ticker := time.NewTicker(statFallbackInterval)
+	defer ticker.Stop()
+
+	if r.watcher == nil {
+		for {
+			select {
+			case <-ctx.Done():
+				r.stopped.Store(true)
+				return
+			case <-ticker.C:
+				r.Reload()
+			}
+		}
+	}
+
+	defer r.watcher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			r.stopped.Store(true)
+			return
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			r.Reload()
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Warn("TLS cert watcher error", zap.Error(err))
+		case <-ticker.C:
+			r.Reload()
+		}
+	}
+}
+
+// Reload re-reads the CA from disk and the client certificate (from disk, or from the
+// ACMEProvider in ACME mode) and, if both load successfully, swaps each of them in atomically. A
+// failed reload (e.g. a partially-written file) is logged and leaves the previously loaded
+// cert/CA in place.
+func (r *tlsReloader) Reload() {
+	pool, err := loadCAPool(r.cfg)
+	if err != nil {
+		r.logger.Warn("Failed to reload CA certificate, keeping previous cert/CA", zap.Error(err))
+		return
+	}
+	if err := r.reloadCert(); err != nil {
+		r.logger.Warn("Failed to reload client certificate, keeping previous cert/CA", zap.Error(err))
+		return
+	}
+
+	r.mu.Lock()
+	r.caPool = pool
+	r.mu.Unlock()
+	r.logger.Info("Reloaded CA certificate pool")
+}
+
+func (r *tlsReloader) reloadCert() error {
+	if r.acme != nil {
+		cert, err := r.acme.Obtain(context.Background())
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		r.cert = cert
+		r.mu.Unlock()
+
+		notAfter := "unknown"
+		if na := r.acme.NotAfter(); !na.IsZero() {
+			notAfter = na.String()
+		}
+		r.logger.Info("Refreshed ACME client certificate", zap.String("not_after", notAfter))
+		return nil
+	}
+
+	certPresent := hasFile(r.cfg.CertDir, r.cfg.CertName)
+	keyPresent := hasFile(r.cfg.CertDir, r.cfg.KeyName)
+	if r.cfg.Mode == TLSModeClient && !certPresent && !keyPresent {
+		// TLSModeClient only presents a client certificate if the server requests one; with no
+		// cert/key files configured there is nothing to watch or load, same as createClientTLSConfig.
+		return nil
+	}
+
+	cert, err := loadKeyPair(r.cfg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.mu.Unlock()
+
+	notAfter := "unknown"
+	if cert.Leaf != nil {
+		notAfter = cert.Leaf.NotAfter.String()
+	}
+	r.logger.Info("Reloaded client certificate", zap.String("not_after", notAfter))
+	return nil
+}
+
+func (r *tlsReloader) reloadCA() error {
+	pool, err := loadCAPool(r.cfg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.caPool = pool
+	r.mu.Unlock()
+
+	r.logger.Info("Reloaded CA certificate pool")
+	return nil
+}
+
+func (r *tlsReloader) getCertificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+func (r *tlsReloader) getCAPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.caPool
+}
+
+// tlsConfig returns a *tls.Config that always verifies against, and presents, the most recently
+// reloaded cert/CA. RootCAs can't be swapped after a tls.Config is built, so verification is done
+// in VerifyPeerCertificate against the live CA pool instead. serverName is the expected identity
+// of the ClickHouse server (from the DSN host) and is checked against the leaf certificate's
+// DNS names/CN, since InsecureSkipVerify also disables the standard library's own hostname check.
+func (r *tlsReloader) tlsConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.getCertificate(), nil
+		},
+		InsecureSkipVerify: true, // verification happens in VerifyPeerCertificate below
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyAgainstPool(rawCerts, r.getCAPool(), serverName)
+		},
+	}
+}
+
+// verifyAgainstPool builds the server's certificate chain from the raw handshake certificates
+// and verifies it against pool, standing in for the verification tls.Config normally does
+// against a static RootCAs when InsecureSkipVerify is set so the CA pool can be swapped live.
+// serverName is checked against the leaf certificate, mirroring the hostname check the standard
+// handshake performs via tls.Config.ServerName -- without it, any cert signed by a trusted CA
+// would be accepted regardless of which host it was issued for.
+func verifyAgainstPool(rawCerts [][]byte, pool *x509.CertPool, serverName string) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no server certificate presented")
+	}
+
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse server certificate: %w", err)
+		}
+		certs[i] = cert
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+	})
+	return err
+}
+
+// NewTLSMigratorWithReload creates a TLSMigrator whose client certificate and CA pool are watched
+// for changes under tlsCfg.CertDir (via fsnotify, with a periodic re-stat fallback) and hot
+// reloaded for the lifetime of ctx. Use this instead of NewTLSMigrator when a migration may
+// outlive short-lived certificates issued by the CA.
+func NewTLSMigratorWithReload(ctx context.Context, tlsCfg TLSConfig) (*TLSMigrator, error) {
+	logger := getLogger()
+
+	reloader, err := newTLSReloader(ctx, logger, tlsCfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TLSMigrator{
+		logger: logger,
+		reload: reloader,
+	}, nil
+}
+
+// NewTLSMigratorWithACMEReload creates a TLSMigrator whose client certificate is periodically
+// refreshed from acmeProvider (re-obtaining it as it nears expiry) and whose CA pool is watched
+// under tlsCfg.CertDir/CAName, both for the lifetime of ctx. Use this instead of
+// NewTLSMigratorWithReload when the client certificate comes from ACME/step-ca rather than files
+// on disk, e.g. a migration expected to outlive a short ACME certificate lifetime.
+func NewTLSMigratorWithACMEReload(ctx context.Context, tlsCfg TLSConfig, acmeProvider *ACMEProvider) (*TLSMigrator, error) {
+	if acmeProvider == nil {
+		return nil, fmt.Errorf("acmeProvider must not be nil")
+	}
+
+	logger := getLogger()
+
+	reloader, err := newTLSReloader(ctx, logger, tlsCfg, acmeProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TLSMigrator{
+		logger: logger,
+		reload: reloader,
+	}, nil
+}
+
+// Reload triggers an immediate re-read of the client certificate and CA, for callers that prefer
+// to drive reloads explicitly (e.g. on SIGHUP) rather than relying on the watcher. It is a no-op
+// if this migrator was not created via NewTLSMigratorWithReload or NewTLSMigratorWithACMEReload.
+func (tm *TLSMigrator) Reload() {
+	if tm.reload == nil {
+		return
+	}
+	tm.reload.Reload()
+}
@@ -0,0 +1,114 @@
+package schemamigrator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeTestCertFiles generates a self-signed CA and a leaf certificate for dnsName, signed by
+// that CA, and writes cert.pem/key.pem/ca.pem into dir in the layout TLSConfig expects.
+func writeTestCertFiles(t *testing.T, dir, dnsName string) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	leafKeyDER, err := x509.MarshalECPrivateKey(leafKey)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.pem"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: leafKeyDER}), 0o600))
+}
+
+func TestVerifyAgainstPool(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCertFiles(t, dir, "clickhouse.internal")
+
+	pool, err := loadCAPool(TLSConfig{CertDir: dir, CAName: "ca.pem"})
+	require.NoError(t, err)
+
+	cert, err := loadKeyPair(TLSConfig{CertDir: dir, CertName: "cert.pem", KeyName: "key.pem"})
+	require.NoError(t, err)
+	rawCerts := cert.Certificate
+
+	err = verifyAgainstPool(rawCerts, pool, "clickhouse.internal")
+	assert.NoError(t, err, "cert with a matching DNS SAN must verify")
+
+	err = verifyAgainstPool(rawCerts, pool, "some-other-host.internal")
+	assert.Error(t, err, "cert presented for an unrelated host must be rejected")
+
+	err = verifyAgainstPool(nil, pool, "clickhouse.internal")
+	assert.EqualError(t, err, "no server certificate presented")
+}
+
+func TestTLSReloaderReloadKeepsPreviousOnPartialWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCertFiles(t, dir, "clickhouse.internal")
+
+	r := &tlsReloader{
+		logger: zap.NewNop(),
+		cfg: TLSConfig{
+			Mode:     TLSModeMTLS,
+			CertDir:  dir,
+			CertName: "cert.pem",
+			KeyName:  "key.pem",
+			CAName:   "ca.pem",
+		},
+	}
+	require.NoError(t, r.reloadCert())
+	require.NoError(t, r.reloadCA())
+
+	goodCert := r.getCertificate()
+	goodPool := r.getCAPool()
+	require.NotNil(t, goodCert)
+	require.NotNil(t, goodPool)
+
+	// Simulate a reader catching the cert file mid-write by an issuer rotating it.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), []byte("not a cert"), 0o600))
+
+	r.Reload()
+
+	assert.Same(t, goodCert, r.getCertificate(), "a failed reload must keep the previously loaded certificate")
+	assert.Same(t, goodPool, r.getCAPool(), "a failed cert reload must not disturb the CA pool")
+}